@@ -0,0 +1,242 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Algorithm names accepted by the --algorithm flag.
+const (
+	AlgorithmSahilm        = "sahilm"
+	AlgorithmSubstring     = "substring"
+	AlgorithmSmithWaterman = "smith-waterman"
+	AlgorithmSubsequence   = "subsequence"
+)
+
+// Scoring bonuses and penalties for the Smith-Waterman style ranker, tuned to
+// match the fzf family of fuzzy finders.
+const (
+	swScoreMatch       = 16
+	swScoreConsecutive = 15
+	swScoreBoundary    = 30
+	swScoreCamelCase   = 8
+	swScoreGapOpen     = -3
+	swScoreGapExtend   = -1
+)
+
+// negInf marks an alignment cell that can't be reached; kept well away from
+// zero so it never wins a max() against a real (possibly negative) score.
+const negInf = -1 << 30
+
+// isBoundary reports whether cur starts a new "word" following prev, i.e. cur
+// is the first character (prev == 0), prev is a path/identifier separator, or
+// cur is an upper-case letter following a lower-case one.
+func isBoundary(prev, cur rune) bool {
+	if prev == 0 {
+		return true
+	}
+	switch prev {
+	case '/', '_', '-', '.', ' ':
+		return true
+	}
+	return isCamelCase(prev, cur)
+}
+
+// isCamelCase reports whether cur is the upper-case start of a new camelCase
+// word following the lower-case prev.
+func isCamelCase(prev, cur rune) bool {
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// runeByteOffsets returns, for each rune in s, the byte offset at which it
+// starts. View() highlights matches by ranging over match.Str directly (a
+// string range, so byte offsets), so any MatchedIndexes computed over
+// []rune(s) must be translated back through this before being returned.
+func runeByteOffsets(s string) []int {
+	offsets := make([]int, 0, len(s))
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return offsets
+}
+
+// containsSubsequence reports whether query's runes appear, in order, inside
+// s. It's used to cheaply prefilter candidates before the O(n*m) scoring
+// pass below.
+func containsSubsequence(query, s string) bool {
+	qr := []rune(query)
+	if len(qr) == 0 {
+		return true
+	}
+	qi := 0
+	for _, c := range s {
+		if c == qr[qi] {
+			qi++
+			if qi == len(qr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// smithWatermanScore finds the best-scoring subsequence alignment of query
+// within choice using fzf-style bonuses for consecutive matches and word
+// boundaries, and affine gap penalties for the characters skipped in
+// between. It returns the alignment score and the matched indexes, as byte
+// offsets into choice, for the existing highlight loop in View.
+func smithWatermanScore(query, choice string) (int, []int) {
+	q := []rune(strings.ToLower(query))
+	c := []rune(choice)
+	cl := []rune(strings.ToLower(choice))
+	n, m := len(cl), len(q)
+	if n == 0 || m == 0 {
+		return 0, nil
+	}
+
+	h := make([][]int, n+1)
+	gapLen := make([][]int, n+1)
+	matched := make([][]bool, n+1)
+	for i := range h {
+		h[i] = make([]int, m+1)
+		gapLen[i] = make([]int, m+1)
+		matched[i] = make([]bool, m+1)
+	}
+	// Row 0 is the "zero choice characters consumed" state: it can only
+	// align with j == 0 query characters, so every h[0][j] for j >= 1 must
+	// be unreachable. Leaving it at the int zero-value instead makes
+	// matching j query characters against nothing look like a free win,
+	// which both corrupts scores and lets the traceback below walk i
+	// past 0.
+	for j := 1; j <= m; j++ {
+		h[0][j] = negInf
+	}
+
+	for i := 1; i <= n; i++ {
+		var prev rune
+		if i > 1 {
+			prev = c[i-2]
+		}
+		for j := 1; j <= m; j++ {
+			matchScore := negInf
+			if cl[i-1] == q[j-1] && h[i-1][j-1] > negInf/2 {
+				bonus := swScoreMatch
+				if isBoundary(prev, c[i-1]) {
+					bonus += swScoreBoundary
+				} else if isCamelCase(prev, c[i-1]) {
+					bonus += swScoreCamelCase
+				}
+				if h[i-1][j-1] > 0 && matched[i-1][j-1] {
+					bonus += swScoreConsecutive
+				}
+				matchScore = h[i-1][j-1] + bonus
+			}
+
+			skipScore := negInf
+			if h[i-1][j] > negInf/2 {
+				penalty := swScoreGapOpen
+				if gapLen[i-1][j] > 0 {
+					penalty = swScoreGapExtend
+				}
+				skipScore = h[i-1][j] + penalty
+			}
+
+			if matchScore >= skipScore {
+				h[i][j] = matchScore
+				matched[i][j] = true
+			} else {
+				h[i][j] = skipScore
+				matched[i][j] = false
+				gapLen[i][j] = gapLen[i-1][j] + 1
+			}
+		}
+	}
+
+	// The alignment may end at any choice character, so characters after the
+	// last match cost nothing: take the best h[i][m] over every i.
+	best, end := negInf, 0
+	for i := m; i <= n; i++ {
+		if h[i][m] > best {
+			best, end = h[i][m], i
+		}
+	}
+	if best <= negInf/2 {
+		return 0, nil
+	}
+
+	byteOffsets := runeByteOffsets(choice)
+	indexes := make([]int, 0, m)
+	i, j := end, m
+	for i > 0 && j > 0 {
+		if matched[i][j] {
+			indexes = append(indexes, byteOffsets[i-1])
+			i--
+			j--
+		} else {
+			i--
+		}
+	}
+	for l, r := 0, len(indexes)-1; l < r; l, r = l+1, r-1 {
+		indexes[l], indexes[r] = indexes[r], indexes[l]
+	}
+
+	return best, indexes
+}
+
+// smithWatermanMatches ranks choices against query using smithWatermanScore,
+// early-out skipping anything that doesn't contain query as a subsequence,
+// and stable-sorts the survivors by (score desc, original index asc).
+func smithWatermanMatches(query string, choices []string) []fuzzy.Match {
+	lowerQuery := strings.ToLower(query)
+	matches := make([]fuzzy.Match, 0, len(choices))
+	for i, choice := range choices {
+		if !containsSubsequence(lowerQuery, strings.ToLower(choice)) {
+			continue
+		}
+		score, indexes := smithWatermanScore(query, choice)
+		matches = append(matches, fuzzy.Match{
+			Str:            choice,
+			Index:          i,
+			MatchedIndexes: indexes,
+			Score:          score,
+		})
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].Index < matches[b].Index
+	})
+	return matches
+}
+
+// subsequenceMatches matches choices containing query's runes in order,
+// without any fzf-style scoring, highlighting the first subsequence found.
+func subsequenceMatches(query string, choices []string) []fuzzy.Match {
+	q := []rune(strings.ToLower(query))
+	matches := make([]fuzzy.Match, 0, len(choices))
+	for i, choice := range choices {
+		lower := []rune(strings.ToLower(choice))
+		byteOffsets := runeByteOffsets(choice)
+		indexes := make([]int, 0, len(q))
+		qi := 0
+		for ci, c := range lower {
+			if qi < len(q) && c == q[qi] {
+				indexes = append(indexes, byteOffsets[ci])
+				qi++
+			}
+		}
+		if qi < len(q) {
+			continue
+		}
+		matches = append(matches, fuzzy.Match{
+			Str:            choice,
+			Index:          i,
+			MatchedIndexes: indexes,
+		})
+	}
+	return matches
+}
@@ -0,0 +1,114 @@
+package filter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestSmithWatermanScoreNoCrashOnShortChoice guards against a DP
+// initialization bug where h[0][j] was left at its zero-value instead of
+// negInf, making "j>0 query characters matched against zero choice
+// characters" look reachable. That let the traceback walk i past 0 and
+// panic with an out-of-range index on otherwise ordinary input.
+func TestSmithWatermanScoreNoCrashOnShortChoice(t *testing.T) {
+	query := "ca_"
+	choice := "ac--_AA/ab_C"
+
+	if !containsSubsequence(query, strings.ToLower(choice)) {
+		t.Fatalf("test setup invalid: %q is not a subsequence of %q", query, choice)
+	}
+
+	score, indexes := smithWatermanScore(query, choice)
+	if score <= 0 {
+		t.Fatalf("expected a positive score for a real match, got %d", score)
+	}
+	if len(indexes) != len([]rune(query)) {
+		t.Fatalf("expected %d matched indexes, got %d (%v)", len([]rune(query)), len(indexes), indexes)
+	}
+	for i := 1; i < len(indexes); i++ {
+		if indexes[i] <= indexes[i-1] {
+			t.Fatalf("matched indexes must be strictly increasing, got %v", indexes)
+		}
+	}
+}
+
+// TestSmithWatermanMatchesRanksBoundaryHigher checks that a match starting
+// at a word boundary (after '_') outscores an equivalent match buried mid
+// word, which is the whole point of the fzf-style bonuses.
+func TestSmithWatermanMatchesRanksBoundaryHigher(t *testing.T) {
+	matches := smithWatermanMatches("ab", []string{"xxabxx", "x_abxx"})
+
+	if len(matches) != 2 {
+		t.Fatalf("expected both choices to match, got %d", len(matches))
+	}
+	if matches[0].Str != "x_abxx" {
+		t.Fatalf("expected boundary match to rank first, got order %v", matches)
+	}
+}
+
+// TestSmithWatermanScoreByteOffsets guards against indexes being returned
+// as rune positions: View() highlights matches by ranging over match.Str
+// directly, which yields byte offsets, so any multi-byte rune ahead of a
+// match desyncs a rune-indexed result from what actually gets highlighted.
+func TestSmithWatermanScoreByteOffsets(t *testing.T) {
+	choice := "café_abc" // "é" is 2 bytes, so byte and rune offsets diverge after it
+	_, indexes := smithWatermanScore("abc", choice)
+
+	want := []int{6, 7, 8}
+	if len(indexes) != len(want) {
+		t.Fatalf("expected %d matched indexes, got %d (%v)", len(want), len(indexes), indexes)
+	}
+	for i, idx := range indexes {
+		if idx != want[i] {
+			t.Fatalf("expected byte offsets %v, got %v", want, indexes)
+		}
+		if choice[idx] != "abc"[i] {
+			t.Fatalf("byte offset %d should point at %q, points at %q", idx, string("abc"[i]), string(choice[idx]))
+		}
+	}
+}
+
+// TestSubsequenceMatchesByteOffsets exercises the same rune/byte desync in
+// the simpler subsequence matcher.
+func TestSubsequenceMatchesByteOffsets(t *testing.T) {
+	matches := subsequenceMatches("abc", []string{"café_abc"})
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+
+	// The first subsequence takes the 'a' of "café" (byte 1), then the 'b'
+	// and 'c' after the 2-byte "é" (bytes 7 and 8, runes 6 and 7).
+	want := []int{1, 7, 8}
+	got := matches[0].MatchedIndexes
+	if len(got) != len(want) {
+		t.Fatalf("expected %d matched indexes, got %d (%v)", len(want), len(got), got)
+	}
+	for i, idx := range got {
+		if idx != want[i] {
+			t.Fatalf("expected byte offsets %v, got %v", want, got)
+		}
+	}
+}
+
+// TestMatchCmdSubstringAlgorithm checks that --algorithm substring actually
+// dispatches to a substring matcher instead of silently falling through to
+// the default sahilm/fuzzy ranking.
+func TestMatchCmdSubstringAlgorithm(t *testing.T) {
+	choices := []string{"banana", "apple", "bandana"}
+
+	msg := matchCmd(context.Background(), "ban", choices, nil, true, AlgorithmSubstring, true, 0)()
+	matches, ok := msg.(matchesMsg)
+	if !ok {
+		t.Fatalf("expected matchesMsg, got %T", msg)
+	}
+
+	if len(matches.matches) != 2 {
+		t.Fatalf("expected 2 substring matches for %q, got %d (%v)", "ban", len(matches.matches), matches.matches)
+	}
+	for _, m := range matches.matches {
+		if !strings.Contains(strings.ToLower(m.Str), "ban") {
+			t.Fatalf("match %q does not contain the query as a substring", m.Str)
+		}
+	}
+}
@@ -8,13 +8,20 @@
 // I.e. let's pick from a list of gum flavors:
 //
 // $ cat flavors.text | gum filter
+//
+// Filter is also usable as a library from other Bubble Tea programs: build
+// one with New and a handful of Options, then Run it to get the selection
+// back, or embed it as a component and drive it through its exported
+// setters.
 package filter
 
 import (
+	"context"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,8 +29,16 @@ import (
 	"github.com/sahilm/fuzzy"
 )
 
-func defaultKeymap() keymap {
-	return keymap{
+// defaultSpinner returns the spinner shown next to the header while a
+// matchCmd is in flight.
+func defaultSpinner() spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.Line
+	return s
+}
+
+func DefaultKeymap() Keymap {
+	return Keymap{
 		Down: key.NewBinding(
 			key.WithKeys("down", "ctrl+j", "ctrl+n"),
 		),
@@ -50,6 +65,16 @@ func defaultKeymap() keymap {
 			key.WithHelp("ctrl+a", "select all"),
 			key.WithDisabled(),
 		),
+		InvertSelection: key.NewBinding(
+			key.WithKeys("ctrl+/"),
+			key.WithHelp("ctrl+/", "invert selection"),
+			key.WithDisabled(),
+		),
+		ShowSelected: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "show selected"),
+			key.WithDisabled(),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "quit"),
@@ -65,23 +90,42 @@ func defaultKeymap() keymap {
 	}
 }
 
-type keymap struct {
+type Keymap struct {
 	Down,
 	Up,
 	ToggleAndNext,
 	ToggleAndPrevious,
 	ToggleAll,
 	Toggle,
+	InvertSelection,
+	ShowSelected,
 	Abort,
 	Quit,
 	Submit key.Binding
 }
 
+// withMultiSelectKeysEnabled returns km with the bindings that only make
+// sense in multi-select mode (ToggleAndNext, ToggleAndPrevious, Toggle,
+// ToggleAll, InvertSelection, and ShowSelected) enabled or disabled to
+// match. DefaultKeymap ships them disabled since New defaults to
+// single-select (limit 1); callers that change the limit need to flip them
+// back, or key.Matches will keep reporting no match regardless of what's
+// pressed.
+func withMultiSelectKeysEnabled(km Keymap, enabled bool) Keymap {
+	km.ToggleAndNext.SetEnabled(enabled)
+	km.ToggleAndPrevious.SetEnabled(enabled)
+	km.Toggle.SetEnabled(enabled)
+	km.ToggleAll.SetEnabled(enabled)
+	km.InvertSelection.SetEnabled(enabled)
+	km.ShowSelected.SetEnabled(enabled)
+	return km
+}
+
 // FullHelp implements help.KeyMap.
-func (k keymap) FullHelp() [][]key.Binding { return nil }
+func (k Keymap) FullHelp() [][]key.Binding { return nil }
 
 // ShortHelp implements help.KeyMap.
-func (k keymap) ShortHelp() []key.Binding {
+func (k Keymap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		key.NewBinding(
 			key.WithKeys("up", "down"),
@@ -93,14 +137,17 @@ func (k keymap) ShortHelp() []key.Binding {
 	}
 }
 
-type model struct {
+type Filter struct {
 	textinput             textinput.Model
 	viewport              *viewport.Model
 	choices               []string
 	matches               []fuzzy.Match
+	matchOffset           int
 	cursor                int
 	header                string
-	selected              map[string]struct{}
+	selected              map[int]struct{}
+	showSelected          bool
+	preselect             []string
 	limit                 int
 	numSelected           int
 	indicator             string
@@ -117,17 +164,39 @@ type model struct {
 	unselectedPrefixStyle lipgloss.Style
 	reverse               bool
 	fuzzy                 bool
+	algorithm             string
 	sort                  bool
 	showHelp              bool
-	keymap                keymap
+	keymap                Keymap
 	help                  help.Model
 	strict                bool
 	submitted             bool
+	stream                bool
+	choicesCh             chan []string
+	matching              bool
+	matchCancel           context.CancelFunc
+	spinner               spinner.Model
+	matcher               Matcher
+	program               *tea.Program
+	previewCmd            string
+	previewWindow         PreviewWindow
+	previewViewport       *viewport.Model
+	previewSeq            int
+	previewCancel         context.CancelFunc
 }
 
-func (m model) Init() tea.Cmd { return textinput.Blink }
+func (m Filter) Init() tea.Cmd {
+	cmds := []tea.Cmd{textinput.Blink}
+	if m.stream {
+		cmds = append(cmds, m.spinner.Tick, waitForChoicesCmd(m.choicesCh))
+	}
+	if m.previewCmd != "" {
+		cmds = append(cmds, m.schedulePreview())
+	}
+	return tea.Batch(cmds...)
+}
 
-func (m model) View() string {
+func (m Filter) View() string {
 	if m.quitting {
 		return ""
 	}
@@ -135,22 +204,24 @@ func (m model) View() string {
 	var s strings.Builder
 	var lineTextStyle lipgloss.Style
 
+	matches := m.visibleMatches()
+
 	// For reverse layout, if the number of matches is less than the viewport
 	// height, we need to offset the matches so that the first match is at the
 	// bottom edge of the viewport instead of in the middle.
-	if m.reverse && len(m.matches) < m.viewport.Height {
-		s.WriteString(strings.Repeat("\n", m.viewport.Height-len(m.matches)))
+	if m.reverse && len(matches) < m.viewport.Height {
+		s.WriteString(strings.Repeat("\n", m.viewport.Height-len(matches)))
 	}
 
 	// Since there are matches, display them so that the user can see, in real
 	// time, what they are searching for.
-	last := len(m.matches) - 1
-	for i := range m.matches {
+	last := len(matches) - 1
+	for i := range matches {
 		// For reverse layout, the matches are displayed in reverse order.
 		if m.reverse {
 			i = last - i
 		}
-		match := m.matches[i]
+		match := matches[i]
 
 		// If this is the current selected index, we add a small indicator to
 		// represent it. Otherwise, simply pad the string.
@@ -165,7 +236,7 @@ func (m model) View() string {
 		}
 
 		// If there are multiple selections mark them, otherwise leave an empty space
-		if _, ok := m.selected[match.Str]; ok {
+		if _, ok := m.selected[m.stableIndex(match.Index)]; ok {
 			s.WriteString(m.selectedPrefixStyle.Render(m.selectedPrefix))
 		} else if m.limit > 1 {
 			s.WriteString(m.unselectedPrefixStyle.Render(m.unselectedPrefix))
@@ -211,35 +282,61 @@ func (m model) View() string {
 		help = m.helpView()
 	}
 
-	// View the input and the filtered choices
-	header := m.headerStyle.Render(m.header)
+	// View the input and the filtered choices. While a matchCmd is still
+	// in flight (every non-empty query is matched off the UI goroutine) a small
+	// spinner is shown next to the header so the user knows more results
+	// may still arrive.
+	headerText := m.header
+	if m.matching {
+		headerText = m.spinner.View() + " " + headerText
+	}
+	header := m.headerStyle.Render(headerText)
 	if m.reverse {
 		view := m.viewport.View() + "\n" + m.textinput.View()
 		if m.showHelp {
 			view += help
 		}
-		if m.header != "" {
-			return lipgloss.JoinVertical(lipgloss.Left, view, header)
+		if m.header != "" || m.matching {
+			view = lipgloss.JoinVertical(lipgloss.Left, view, header)
 		}
 
-		return view
+		return m.withPreview(view)
 	}
 
 	view := m.textinput.View() + "\n" + m.viewport.View()
 	if m.showHelp {
 		view += help
 	}
-	if m.header != "" {
-		return lipgloss.JoinVertical(lipgloss.Left, header, view)
+	if m.header != "" || m.matching {
+		view = lipgloss.JoinVertical(lipgloss.Left, header, view)
+	}
+	return m.withPreview(view)
+}
+
+// withPreview lays the preview pane out alongside view according to
+// m.previewWindow.Position, or returns view unchanged if no preview command
+// is configured.
+func (m Filter) withPreview(view string) string {
+	if m.previewCmd == "" {
+		return view
+	}
+
+	preview := m.previewViewport.View()
+	switch m.previewWindow.Position {
+	case PreviewRight:
+		return lipgloss.JoinHorizontal(lipgloss.Top, view, preview)
+	case PreviewTop:
+		return lipgloss.JoinVertical(lipgloss.Left, preview, view)
+	default: // PreviewBottom
+		return lipgloss.JoinVertical(lipgloss.Left, view, preview)
 	}
-	return view
 }
 
-func (m model) helpView() string {
+func (m Filter) helpView() string {
 	return "\n\n" + m.help.View(m.keymap)
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m Filter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd, icmd tea.Cmd
 	m.textinput, icmd = m.textinput.Update(msg)
 	switch msg := msg.(type) {
@@ -256,6 +353,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.Height = m.viewport.Height - lipgloss.Height(m.helpView())
 		}
 		m.viewport.Width = msg.Width
+
+		// Carve the preview pane's space out of the total, and give it the
+		// rest of the dimension it doesn't own.
+		if m.previewCmd != "" {
+			previewWidth, previewHeight := m.previewWindow.dimensions(msg.Width, msg.Height)
+			m.previewViewport.Width = previewWidth
+			m.previewViewport.Height = previewHeight
+			switch m.previewWindow.Position {
+			case PreviewRight:
+				m.viewport.Width = msg.Width - previewWidth
+			case PreviewTop, PreviewBottom:
+				m.viewport.Height -= previewHeight
+			}
+		}
+
 		if m.reverse {
 			m.viewport.YOffset = clamp(0, len(m.matches), len(m.matches)-m.viewport.Height)
 		}
@@ -273,21 +385,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.submitted = true
 			return m, tea.Quit
 		case key.Matches(msg, km.Down):
-			m.CursorDown()
+			cmd = m.CursorDown()
 		case key.Matches(msg, km.Up):
-			m.CursorUp()
+			cmd = m.CursorUp()
 		case key.Matches(msg, km.ToggleAndNext):
 			if m.limit == 1 {
 				break // no op
 			}
 			m.ToggleSelection()
-			m.CursorDown()
+			cmd = m.CursorDown()
 		case key.Matches(msg, km.ToggleAndPrevious):
 			if m.limit == 1 {
 				break // no op
 			}
 			m.ToggleSelection()
-			m.CursorUp()
+			cmd = m.CursorUp()
 		case key.Matches(msg, km.Toggle):
 			if m.limit == 1 {
 				break // no op
@@ -302,6 +414,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m = m.deselectAll()
 			}
+		case key.Matches(msg, km.InvertSelection):
+			if m.limit <= 1 {
+				break
+			}
+			m = m.invertSelection()
+		case key.Matches(msg, km.ShowSelected):
+			if m.limit <= 1 {
+				break
+			}
+			m.showSelected = !m.showSelected
+			m.cursor = 0
 		default:
 			// yOffsetFromBottom is the number of lines from the bottom of the
 			// list to the top of the viewport. This is used to keep the viewport
@@ -313,80 +436,202 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			// A character was entered, this likely means that the text input has
-			// changed. This suggests that the matches are outdated, so update them.
-			var choices []string
-			if !m.strict {
-				choices = append(choices, m.textinput.Value())
+			// changed. This suggests that the matches are outdated, so
+			// re-filter. The matcher runs off the UI goroutine and reports
+			// back via matchesMsg, canceling whatever matcher was still
+			// running for the previous keystroke.
+			cmd = m.dispatchMatch(yOffsetFromBottom)
+
+			// If the search field is empty, dispatchMatch already filled in
+			// every choice synchronously, but the reverse-layout viewport
+			// still needs to be re-offset for the new match count.
+			if m.reverse && m.textinput.Value() == "" {
+				maxYOffset := max(0, len(m.matches)-m.viewport.Height)
+				m.viewport.YOffset = clamp(0, maxYOffset, len(m.matches)-yOffsetFromBottom)
 			}
-			choices = append(choices, m.choices...)
-			if m.fuzzy {
-				if m.sort {
-					m.matches = fuzzy.Find(m.textinput.Value(), choices)
-				} else {
-					m.matches = fuzzy.FindNoSort(m.textinput.Value(), choices)
-				}
+		}
+	case matchesMsg:
+		// Drop results for a query that's since been superseded by further
+		// typing; the matcher for the latest keystroke is still in flight.
+		if msg.query == m.textinput.Value() {
+			m.matches = msg.matches
+			m.matching = false
+			if !m.strict {
+				m.matchOffset = 1
 			} else {
-				m.matches = exactMatches(m.textinput.Value(), choices)
-			}
-
-			// If the search field is empty, let's not display the matches
-			// (none), but rather display all possible choices.
-			if m.textinput.Value() == "" {
-				m.matches = matchAll(m.choices)
+				m.matchOffset = 0
 			}
-
-			// For reverse layout, we need to offset the viewport so that the
-			// it remains at a constant position relative to the cursor.
 			if m.reverse {
 				maxYOffset := max(0, len(m.matches)-m.viewport.Height)
-				m.viewport.YOffset = clamp(0, maxYOffset, len(m.matches)-yOffsetFromBottom)
+				m.viewport.YOffset = clamp(0, maxYOffset, len(m.matches)-msg.yOffsetFromBottom)
 			}
 		}
+	case submitMsg:
+		m.quitting = true
+		m.submitted = true
+		return m, tea.Quit
+	case choicesAppendedMsg:
+		m.choices = append(m.choices, msg.choices...)
+		var yOffsetFromBottom int
+		if m.reverse {
+			yOffsetFromBottom = max(0, len(m.matches)-m.viewport.YOffset)
+		}
+		cmd = tea.Batch(waitForChoicesCmd(m.choicesCh), m.dispatchMatch(yOffsetFromBottom))
+	case choicesDoneMsg:
+		// stdin has been fully consumed; nothing left to wait on.
+	case choicesSetMsg:
+		// A full replacement via SetChoices invalidates any selection made
+		// against the old list, since stable indexes no longer line up.
+		m.choices = msg.choices
+		m.selected = make(map[int]struct{})
+		m.numSelected = 0
+		var yOffsetFromBottom int
+		if m.reverse {
+			yOffsetFromBottom = max(0, len(m.matches)-m.viewport.YOffset)
+		}
+		cmd = m.dispatchMatch(yOffsetFromBottom)
+	case headerSetMsg:
+		m.header = msg.header
+	case limitSetMsg:
+		m.limit = msg.limit
+		m.keymap = withMultiSelectKeysEnabled(m.keymap, m.limit > 1)
+	case spinner.TickMsg:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case previewRequestedMsg:
+		// A later cursor move has since debounced its own request; this one
+		// is stale and the choice it was for may no longer be selected.
+		if msg.seq == m.previewSeq {
+			cmd = m.dispatchPreview()
+		}
+	case previewMsg:
+		if msg.seq == m.previewSeq {
+			m.previewViewport.SetContent(msg.content)
+		}
 	}
 
-	// It's possible that filtering items have caused fewer matches. So, ensure
-	// that the selected index is within the bounds of the number of matches.
-	m.cursor = clamp(0, len(m.matches)-1, m.cursor)
+	// It's possible that filtering items (or toggling showSelected) have
+	// caused fewer matches to be visible. So, ensure that the selected index
+	// is within the bounds of the number of visible matches.
+	m.cursor = clamp(0, len(m.visibleMatches())-1, m.cursor)
 	return m, tea.Batch(cmd, icmd)
 }
 
-func (m *model) CursorUp() {
-	if len(m.matches) == 0 {
-		return
+// dispatchMatch cancels any in-flight matcher and starts a new one for the
+// current query against the current choices. Called both when the query
+// changes and when new choices stream in while a query is already active.
+func (m *Filter) dispatchMatch(yOffsetFromBottom int) tea.Cmd {
+	if m.matchCancel != nil {
+		m.matchCancel()
+		m.matchCancel = nil
+	}
+
+	query := m.textinput.Value()
+	if query == "" {
+		// Nothing to match against, so skip the async round trip and show
+		// every choice straight away.
+		m.matching = false
+		m.matchOffset = 0
+		m.matches = matchAll(m.choices)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.matchCancel = cancel
+	m.matching = true
+
+	var choices []string
+	if !m.strict {
+		// The typed query itself is prepended as a candidate so the user can
+		// pick "what I typed" even if nothing matches it; it has no stable
+		// index of its own, so every real choice's index is shifted by one.
+		// matchOffset is updated when matchesMsg delivers those results, as
+		// m.matches still holds the previous ones until then.
+		choices = append(choices, query)
+	}
+	choices = append(choices, m.choices...)
+	return matchCmd(ctx, query, choices, m.matcher, m.fuzzy, m.algorithm, m.sort, yOffsetFromBottom)
+}
+
+// stableIndex translates a fuzzy.Match's Index (which is relative to the
+// slice handed to the matcher, and so may be offset by one for the
+// unmatched typed-query candidate) into a stable index into m.choices, so
+// that selections survive the choice reappearing or disappearing as the
+// query changes. It returns -1 for a match that doesn't correspond to a
+// real, persistent choice.
+func (m Filter) stableIndex(matchIndex int) int {
+	idx := matchIndex - m.matchOffset
+	if idx < 0 || idx >= len(m.choices) {
+		return -1
+	}
+	return idx
+}
+
+// visibleMatches returns the matches that should be navigated and rendered:
+// normally m.matches, or - while showSelected is toggled on - every
+// currently selected choice in its original input order, regardless of the
+// active query.
+func (m Filter) visibleMatches() []fuzzy.Match {
+	if !m.showSelected {
+		return m.matches
+	}
+	matches := make([]fuzzy.Match, 0, len(m.selected))
+	for i, choice := range m.choices {
+		if _, ok := m.selected[i]; ok {
+			// Index must carry the same matchOffset that stableIndex
+			// (called by every consumer of visibleMatches) subtracts back
+			// off, or it'll translate this already-stable index wrong.
+			matches = append(matches, fuzzy.Match{Str: choice, Index: i + m.matchOffset})
+		}
+	}
+	return matches
+}
+
+// CursorUp moves the cursor up (or, in reverse layout, down the underlying
+// list) and, if a preview command is configured, debounces a refresh of the
+// preview pane for the newly selected choice.
+func (m *Filter) CursorUp() tea.Cmd {
+	n := len(m.visibleMatches())
+	if n == 0 {
+		return nil
 	}
 	if m.reverse { //nolint:nestif
-		m.cursor = (m.cursor + 1) % len(m.matches)
-		if len(m.matches)-m.cursor <= m.viewport.YOffset {
+		m.cursor = (m.cursor + 1) % n
+		if n-m.cursor <= m.viewport.YOffset {
 			m.viewport.LineUp(1)
 		}
-		if len(m.matches)-m.cursor > m.viewport.Height+m.viewport.YOffset {
-			m.viewport.SetYOffset(len(m.matches) - m.viewport.Height)
+		if n-m.cursor > m.viewport.Height+m.viewport.YOffset {
+			m.viewport.SetYOffset(n - m.viewport.Height)
 		}
 	} else {
-		m.cursor = (m.cursor - 1 + len(m.matches)) % len(m.matches)
+		m.cursor = (m.cursor - 1 + n) % n
 		if m.cursor < m.viewport.YOffset {
 			m.viewport.LineUp(1)
 		}
 		if m.cursor >= m.viewport.YOffset+m.viewport.Height {
-			m.viewport.SetYOffset(len(m.matches) - m.viewport.Height)
+			m.viewport.SetYOffset(n - m.viewport.Height)
 		}
 	}
+	return m.schedulePreview()
 }
 
-func (m *model) CursorDown() {
-	if len(m.matches) == 0 {
-		return
+// CursorDown moves the cursor down (or, in reverse layout, up the
+// underlying list) and, if a preview command is configured, debounces a
+// refresh of the preview pane for the newly selected choice.
+func (m *Filter) CursorDown() tea.Cmd {
+	n := len(m.visibleMatches())
+	if n == 0 {
+		return nil
 	}
 	if m.reverse { //nolint:nestif
-		m.cursor = (m.cursor - 1 + len(m.matches)) % len(m.matches)
-		if len(m.matches)-m.cursor > m.viewport.Height+m.viewport.YOffset {
+		m.cursor = (m.cursor - 1 + n) % n
+		if n-m.cursor > m.viewport.Height+m.viewport.YOffset {
 			m.viewport.LineDown(1)
 		}
-		if len(m.matches)-m.cursor <= m.viewport.YOffset {
+		if n-m.cursor <= m.viewport.YOffset {
 			m.viewport.GotoTop()
 		}
 	} else {
-		m.cursor = (m.cursor + 1) % len(m.matches)
+		m.cursor = (m.cursor + 1) % n
 		if m.cursor >= m.viewport.YOffset+m.viewport.Height {
 			m.viewport.LineDown(1)
 		}
@@ -394,42 +639,124 @@ func (m *model) CursorDown() {
 			m.viewport.GotoTop()
 		}
 	}
+	return m.schedulePreview()
 }
 
-func (m *model) ToggleSelection() {
-	if _, ok := m.selected[m.matches[m.cursor].Str]; ok {
-		delete(m.selected, m.matches[m.cursor].Str)
+// ToggleSelection toggles the choice under the cursor. Selections are keyed
+// by the choice's stable index (see stableIndex), not its string value, so
+// that duplicate choices don't collapse into a single selection and toggled
+// items keep their state as the query changes.
+func (m *Filter) ToggleSelection() {
+	matches := m.visibleMatches()
+	if m.cursor >= len(matches) {
+		return
+	}
+	idx := m.stableIndex(matches[m.cursor].Index)
+	if idx < 0 {
+		return
+	}
+	if _, ok := m.selected[idx]; ok {
+		delete(m.selected, idx)
 		m.numSelected--
 	} else if m.numSelected < m.limit {
-		m.selected[m.matches[m.cursor].Str] = struct{}{}
+		m.selected[idx] = struct{}{}
 		m.numSelected++
 	}
 }
 
-func (m model) selectAll() model {
-	for i := range m.matches {
+func (m Filter) selectAll() Filter {
+	for _, match := range m.matches {
 		if m.numSelected >= m.limit {
 			break // do not exceed given limit
 		}
-		if _, ok := m.selected[m.matches[i].Str]; ok {
+		idx := m.stableIndex(match.Index)
+		if idx < 0 {
+			continue
+		}
+		if _, ok := m.selected[idx]; ok {
 			continue
 		}
-		m.selected[m.matches[i].Str] = struct{}{}
+		m.selected[idx] = struct{}{}
 		m.numSelected++
 	}
 	return m
 }
 
-func (m model) deselectAll() model {
-	m.selected = make(map[string]struct{})
+func (m Filter) deselectAll() Filter {
+	m.selected = make(map[int]struct{})
 	m.numSelected = 0
 	return m
 }
 
+// invertSelection flips the selection state of every choice in the current
+// match set (i.e. the active query's results, not the full choice list).
+func (m Filter) invertSelection() Filter {
+	for _, match := range m.matches {
+		idx := m.stableIndex(match.Index)
+		if idx < 0 {
+			continue
+		}
+		if _, ok := m.selected[idx]; ok {
+			delete(m.selected, idx)
+			m.numSelected--
+		} else if m.numSelected < m.limit {
+			m.selected[idx] = struct{}{}
+			m.numSelected++
+		}
+	}
+	return m
+}
+
+// applyPreselect marks every choice whose value is in values as selected,
+// honoring the configured limit. It's meant to be called once, with the
+// choices parsed from a --preselect file or literal list, to restore a
+// selection from a previous run of the program.
+func (m *Filter) applyPreselect(values []string) {
+	if len(values) == 0 {
+		return
+	}
+	want := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		want[v] = struct{}{}
+	}
+	for i, choice := range m.choices {
+		if m.numSelected >= m.limit {
+			break
+		}
+		if _, ok := want[choice]; ok {
+			m.selected[i] = struct{}{}
+			m.numSelected++
+		}
+	}
+}
+
+// SelectedChoices returns the selected choices in their original input
+// order, rather than the order in which they were toggled. If nothing has
+// been toggled and at most one choice may be selected, it falls back to
+// whatever is under the cursor, matching the single-select behavior.
+func (m Filter) SelectedChoices() []string {
+	if m.numSelected == 0 {
+		if m.limit == 1 {
+			matches := m.visibleMatches()
+			if m.cursor < len(matches) {
+				return []string{matches[m.cursor].Str}
+			}
+		}
+		return nil
+	}
+	choices := make([]string, 0, m.numSelected)
+	for i, choice := range m.choices {
+		if _, ok := m.selected[i]; ok {
+			choices = append(choices, choice)
+		}
+	}
+	return choices
+}
+
 func matchAll(options []string) []fuzzy.Match {
 	matches := make([]fuzzy.Match, len(options))
 	for i, option := range options {
-		matches[i] = fuzzy.Match{Str: option}
+		matches[i] = fuzzy.Match{Str: option, Index: i}
 	}
 	return matches
 }
@@ -0,0 +1,73 @@
+package filter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestMultiSelectKeysEnabledWhenLimitGreaterThanOne guards against
+// DefaultKeymap shipping the multi-select bindings disabled and nothing
+// ever re-enabling them: key.Matches returns false for a disabled binding
+// no matter what's pressed, so ctrl+o, ctrl+/, tab and ctrl+a would never
+// fire even with WithLimit(3).
+func TestMultiSelectKeysEnabledWhenLimitGreaterThanOne(t *testing.T) {
+	f := New(WithChoices([]string{"a", "b", "c"}), WithLimit(3))
+
+	if !f.keymap.ToggleAndNext.Enabled() {
+		t.Error("ToggleAndNext should be enabled when limit > 1")
+	}
+	if !f.keymap.ToggleAndPrevious.Enabled() {
+		t.Error("ToggleAndPrevious should be enabled when limit > 1")
+	}
+	if !f.keymap.Toggle.Enabled() {
+		t.Error("Toggle should be enabled when limit > 1")
+	}
+	if !f.keymap.ToggleAll.Enabled() {
+		t.Error("ToggleAll should be enabled when limit > 1")
+	}
+	if !f.keymap.InvertSelection.Enabled() {
+		t.Error("InvertSelection should be enabled when limit > 1")
+	}
+	if !f.keymap.ShowSelected.Enabled() {
+		t.Error("ShowSelected should be enabled when limit > 1")
+	}
+}
+
+// TestMultiSelectKeysDisabledInSingleSelect checks the default (limit 1)
+// case stays disabled, since none of these bindings make sense there.
+func TestMultiSelectKeysDisabledInSingleSelect(t *testing.T) {
+	f := New(WithChoices([]string{"a", "b", "c"}))
+
+	if f.keymap.ToggleAndNext.Enabled() {
+		t.Error("ToggleAndNext should stay disabled in single-select mode")
+	}
+	if f.keymap.ToggleAndPrevious.Enabled() {
+		t.Error("ToggleAndPrevious should stay disabled in single-select mode")
+	}
+	if f.keymap.Toggle.Enabled() {
+		t.Error("Toggle should stay disabled in single-select mode")
+	}
+	if f.keymap.ToggleAll.Enabled() {
+		t.Error("ToggleAll should stay disabled in single-select mode")
+	}
+	if f.keymap.InvertSelection.Enabled() {
+		t.Error("InvertSelection should stay disabled in single-select mode")
+	}
+	if f.keymap.ShowSelected.Enabled() {
+		t.Fatal("ShowSelected should stay disabled in single-select mode")
+	}
+}
+
+// TestShowSelectedKeyTogglesWhenMultiSelect exercises the bug end to end:
+// pressing ctrl+o against a WithLimit(3) Filter should flip showSelected.
+func TestShowSelectedKeyTogglesWhenMultiSelect(t *testing.T) {
+	f := New(WithChoices([]string{"a", "b", "c"}), WithLimit(3))
+
+	model, _ := Filter(*f).Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	updated := model.(Filter)
+
+	if !updated.showSelected {
+		t.Fatal("ctrl+o should toggle showSelected once the limit allows multi-select")
+	}
+}
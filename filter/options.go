@@ -0,0 +1,159 @@
+package filter
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// Matcher ranks choices against a query. Implement it and pass it via
+// WithMatcher to swap in a custom scoring algorithm without having to shell
+// out to the gum filter binary.
+type Matcher interface {
+	Match(query string, choices []string) []fuzzy.Match
+}
+
+// Option configures a Filter created with New.
+type Option func(*Filter)
+
+// WithChoices sets the initial list of choices to filter over.
+func WithChoices(choices []string) Option {
+	return func(f *Filter) { f.choices = choices }
+}
+
+// WithHeader sets the text shown above the filter.
+func WithHeader(header string) Option {
+	return func(f *Filter) { f.header = header }
+}
+
+// WithLimit sets the maximum number of choices that may be selected. A
+// limit of 1 (the default) is single-select.
+func WithLimit(limit int) Option {
+	return func(f *Filter) { f.limit = limit }
+}
+
+// WithFuzzy enables or disables fuzzy matching. With it disabled, filtering
+// falls back to a plain substring match.
+func WithFuzzy(fuzzy bool) Option {
+	return func(f *Filter) { f.fuzzy = fuzzy }
+}
+
+// WithAlgorithm selects which fuzzy scoring backend to use (AlgorithmSahilm,
+// AlgorithmSubstring, AlgorithmSmithWaterman, or AlgorithmSubsequence). It
+// has no effect unless
+// fuzzy matching is enabled and no custom Matcher is set.
+func WithAlgorithm(algorithm string) Option {
+	return func(f *Filter) { f.algorithm = algorithm }
+}
+
+// WithStrict disables matching against the raw, unmatched query, so only
+// choices that were actually provided can be selected.
+func WithStrict(strict bool) Option {
+	return func(f *Filter) { f.strict = strict }
+}
+
+// WithReverse lays the filter out bottom-up, like a shell's reverse search.
+func WithReverse(reverse bool) Option {
+	return func(f *Filter) { f.reverse = reverse }
+}
+
+// WithSort enables or disables sorting matches by score when using the
+// sahilm/fuzzy algorithm.
+func WithSort(sort bool) Option {
+	return func(f *Filter) { f.sort = sort }
+}
+
+// WithKeymap overrides the default keybindings.
+func WithKeymap(km Keymap) Option {
+	return func(f *Filter) { f.keymap = km }
+}
+
+// WithStyles overrides the default styling of the header, matches, and
+// selection indicators.
+func WithStyles(header, text, cursorText, match, indicator, selectedPrefix, unselectedPrefix lipgloss.Style) Option {
+	return func(f *Filter) {
+		f.headerStyle = header
+		f.textStyle = text
+		f.cursorTextStyle = cursorText
+		f.matchStyle = match
+		f.indicatorStyle = indicator
+		f.selectedPrefixStyle = selectedPrefix
+		f.unselectedPrefixStyle = unselectedPrefix
+	}
+}
+
+// WithMatcher replaces every built-in matching algorithm with a caller
+// provided one.
+func WithMatcher(matcher Matcher) Option {
+	return func(f *Filter) { f.matcher = matcher }
+}
+
+// WithChoicesChannel opts into streaming mode: choices are read as they
+// arrive on ch, as produced by NewChoicesChannel, instead of requiring them
+// all up front via WithChoices. This lets the UI render before stdin (or
+// whatever ch is fed from) has been fully consumed. The Filter drains ch
+// until it's closed; the caller retains no other use for it.
+func WithChoicesChannel(ch chan []string) Option {
+	return func(f *Filter) {
+		f.stream = true
+		f.choicesCh = ch
+	}
+}
+
+// WithPreselected restores a prior selection (e.g. parsed from a
+// --preselect file or literal list). New applies it to the choices given
+// via WithChoices; choices that arrive later through WithChoicesChannel or
+// SetChoices are not preselected.
+func WithPreselected(values []string) Option {
+	return func(f *Filter) { f.preselect = values }
+}
+
+// WithPreview runs template (with {} substituted for the choice under the
+// cursor) through the shell on every cursor move, and shows its output in a
+// pane laid out per window. See ParsePreviewWindow for the window spec
+// syntax.
+func WithPreview(template string, window PreviewWindow) Option {
+	return func(f *Filter) {
+		f.previewCmd = template
+		f.previewWindow = window
+	}
+}
+
+// New creates a Filter with the given options applied over sensible
+// defaults, ready to Run.
+func New(opts ...Option) *Filter {
+	ti := textinput.New()
+	ti.Focus()
+
+	vp := viewport.New(0, 0)
+	pvp := viewport.New(0, 0)
+
+	f := &Filter{
+		textinput:        ti,
+		viewport:         &vp,
+		previewViewport:  &pvp,
+		selected:         make(map[int]struct{}),
+		limit:            1,
+		indicator:        ">",
+		selectedPrefix:   "✓ ",
+		unselectedPrefix: "  ",
+		fuzzy:            true,
+		algorithm:        AlgorithmSahilm,
+		sort:             true,
+		keymap:           DefaultKeymap(),
+		help:             help.New(),
+		spinner:          defaultSpinner(),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	f.keymap = withMultiSelectKeysEnabled(f.keymap, f.limit > 1)
+	f.matches = matchAll(f.choices)
+	f.applyPreselect(f.preselect)
+
+	return f
+}
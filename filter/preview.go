@@ -0,0 +1,168 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewDebounce is how long a cursor move waits before spawning the
+// preview command, so that holding an arrow key down doesn't fork a
+// process per line crossed.
+const previewDebounce = 80 * time.Millisecond
+
+// PreviewPosition is where the preview pane sits relative to the list.
+type PreviewPosition int
+
+// Preview pane placements, as named in a --preview-window flag.
+const (
+	PreviewRight PreviewPosition = iota
+	PreviewTop
+	PreviewBottom
+)
+
+// PreviewWindow configures the preview pane's placement and size, as parsed
+// by ParsePreviewWindow from a --preview-window=position:size flag (e.g.
+// "right:50%", "top:30%", "bottom:10").
+type PreviewWindow struct {
+	Position PreviewPosition
+	Percent  int // 1-100; takes precedence over Fixed when non-zero.
+	Fixed    int // lines (top/bottom) or columns (right), used when Percent is zero.
+}
+
+// ParsePreviewWindow parses a --preview-window flag value of the form
+// "position:size", where position is right, top, or bottom, and size is
+// either a percentage (e.g. "50%") or a fixed number of lines/columns.
+func ParsePreviewWindow(spec string) (PreviewWindow, error) {
+	position, size, ok := strings.Cut(spec, ":")
+	if !ok {
+		return PreviewWindow{}, fmt.Errorf("invalid preview window %q: want position:size", spec)
+	}
+
+	var pw PreviewWindow
+	switch position {
+	case "right":
+		pw.Position = PreviewRight
+	case "top":
+		pw.Position = PreviewTop
+	case "bottom":
+		pw.Position = PreviewBottom
+	default:
+		return PreviewWindow{}, fmt.Errorf("invalid preview window position %q", position)
+	}
+
+	if pct, ok := strings.CutSuffix(size, "%"); ok {
+		n, err := strconv.Atoi(pct)
+		if err != nil {
+			return PreviewWindow{}, fmt.Errorf("invalid preview window size %q: %w", size, err)
+		}
+		if n < 1 || n > 100 {
+			return PreviewWindow{}, fmt.Errorf("invalid preview window size %q: percentage must be 1-100", size)
+		}
+		pw.Percent = n
+		return pw, nil
+	}
+
+	n, err := strconv.Atoi(size)
+	if err != nil {
+		return PreviewWindow{}, fmt.Errorf("invalid preview window size %q: %w", size, err)
+	}
+	pw.Fixed = n
+	return pw, nil
+}
+
+// dimensions returns the preview pane's (width, height) given the total
+// space available to the whole view.
+func (pw PreviewWindow) dimensions(totalWidth, totalHeight int) (width, height int) {
+	switch pw.Position {
+	case PreviewRight:
+		height = totalHeight
+		if pw.Percent > 0 {
+			width = totalWidth * pw.Percent / 100
+		} else {
+			width = pw.Fixed
+		}
+	default: // PreviewTop, PreviewBottom
+		width = totalWidth
+		if pw.Percent > 0 {
+			height = totalHeight * pw.Percent / 100
+		} else {
+			height = pw.Fixed
+		}
+	}
+	return width, height
+}
+
+// previewRequestedMsg fires previewDebounce after a cursor move; it's
+// ignored if a later cursor move has since bumped Filter.previewSeq.
+type previewRequestedMsg struct {
+	seq int
+}
+
+// previewMsg carries the rendered output of a preview command.
+type previewMsg struct {
+	seq     int
+	content string
+}
+
+// schedulePreview debounces a cursor move into a previewRequestedMsg,
+// tagged with a sequence number so a later move can supersede it.
+func (m *Filter) schedulePreview() tea.Cmd {
+	if m.previewCmd == "" {
+		return nil
+	}
+	m.previewSeq++
+	seq := m.previewSeq
+	return tea.Tick(previewDebounce, func(time.Time) tea.Msg {
+		return previewRequestedMsg{seq: seq}
+	})
+}
+
+// dispatchPreview cancels any in-flight preview command and starts one for
+// the choice currently under the cursor.
+func (m *Filter) dispatchPreview() tea.Cmd {
+	if m.previewCancel != nil {
+		m.previewCancel()
+		m.previewCancel = nil
+	}
+
+	matches := m.visibleMatches()
+	if m.cursor >= len(matches) {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.previewCancel = cancel
+	return previewCmd(ctx, m.previewCmd, matches[m.cursor].Str, m.previewSeq)
+}
+
+// previewCmd runs template as a shell command with {} substituted for
+// choice and, once the command exits, sends its combined stdout and stderr
+// back as a single previewMsg, so ANSI output from tools like bat or glow
+// passes through untouched. Nothing is shown while the command is running.
+func previewCmd(ctx context.Context, template, choice string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		command := strings.ReplaceAll(template, "{}", shellQuote(choice))
+		out, err := exec.CommandContext(ctx, "sh", "-c", command).CombinedOutput()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		content := string(out)
+		if err != nil && content == "" {
+			content = err.Error()
+		}
+		return previewMsg{seq: seq, content: content}
+	}
+}
+
+// shellQuote single-quotes s for safe interpolation into a `sh -c` command
+// line, escaping any embedded single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,91 @@
+package filter
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrAborted is returned by Run when the user quits with the quit key (esc)
+// without submitting a selection. Aborting via ctrl+c or Abort instead makes
+// Run return the Bubble Tea program's tea.ErrInterrupted.
+var ErrAborted = errors.New("no selection was made")
+
+// Run starts the filter's Bubble Tea program, blocking until the user
+// submits a selection or aborts. It's the programmatic equivalent of the
+// `gum filter` CLI command.
+func (f *Filter) Run() ([]string, error) {
+	f.matches = matchAll(f.choices)
+
+	p := tea.NewProgram(*f)
+	f.program = p
+
+	finalModel, err := p.Run()
+	f.program = nil
+	if err != nil {
+		return nil, err
+	}
+
+	final := finalModel.(Filter)
+	*f = final
+	if !f.submitted {
+		return nil, ErrAborted
+	}
+	return f.SelectedChoices(), nil
+}
+
+// Quit submits whatever is currently selected (or, in single-select mode,
+// whatever is under the cursor) and stops Run, as if the user had pressed
+// the submit key.
+func (f *Filter) Quit() {
+	if f.program != nil {
+		f.program.Send(submitMsg{})
+	}
+}
+
+// Abort stops Run without submitting a selection, as if the user had
+// pressed the abort key.
+func (f *Filter) Abort() {
+	if f.program != nil {
+		f.program.Send(tea.Interrupt())
+	}
+}
+
+// SetChoices replaces the choices being filtered over. Safe to call either
+// before Run or while it's running (e.g. to feed it choices read
+// incrementally from stdin).
+func (f *Filter) SetChoices(choices []string) {
+	if f.program != nil {
+		f.program.Send(choicesSetMsg{choices: choices})
+		return
+	}
+	f.choices = choices
+	f.matches = matchAll(f.choices)
+	f.selected = make(map[int]struct{})
+	f.numSelected = 0
+}
+
+// SetHeader replaces the text shown above the filter.
+func (f *Filter) SetHeader(header string) {
+	if f.program != nil {
+		f.program.Send(headerSetMsg{header: header})
+		return
+	}
+	f.header = header
+}
+
+// SetLimit replaces the maximum number of choices that may be selected.
+func (f *Filter) SetLimit(limit int) {
+	if f.program != nil {
+		f.program.Send(limitSetMsg{limit: limit})
+		return
+	}
+	f.limit = limit
+	f.keymap = withMultiSelectKeysEnabled(f.keymap, f.limit > 1)
+}
+
+// Selected returns the choices currently selected, in their original input
+// order. It reflects whatever was selected as of the last Run call.
+func (f *Filter) Selected() []string {
+	return f.SelectedChoices()
+}
@@ -0,0 +1,24 @@
+package filter
+
+import "testing"
+
+// TestSubmitMsgMarksSubmitted guards against Quit regressing to a bare
+// tea.Quit(): Bubble Tea's event loop special-cases tea.QuitMsg and returns
+// without ever calling Update, so submitted would never flip to true and
+// Run would report ErrAborted instead of returning the selection.
+func TestSubmitMsgMarksSubmitted(t *testing.T) {
+	f := New(WithChoices([]string{"a", "b", "c"}))
+
+	model, cmd := Filter(*f).Update(submitMsg{})
+	updated := model.(Filter)
+
+	if !updated.submitted {
+		t.Fatal("submitMsg should set submitted so Run() returns the selection instead of ErrAborted")
+	}
+	if !updated.quitting {
+		t.Fatal("submitMsg should stop the program like the submit key does")
+	}
+	if cmd == nil {
+		t.Fatal("submitMsg should still issue a quit command")
+	}
+}
@@ -0,0 +1,46 @@
+package filter
+
+import "testing"
+
+// TestVisibleMatchesShowSelectedStableIndex guards against
+// visibleMatches's showSelected branch handing out indexes that
+// stableIndex (applied by every caller) then maps wrong. In non-strict
+// mode, once a query has been typed matchOffset is 1, and stableIndex
+// unconditionally subtracts it; visibleMatches must account for that.
+func TestVisibleMatchesShowSelectedStableIndex(t *testing.T) {
+	f := New(WithChoices([]string{"a", "b", "c"}), WithLimit(3))
+	f.selected[0] = struct{}{}
+	f.numSelected = 1
+	f.matchOffset = 1
+	f.showSelected = true
+
+	matches := f.visibleMatches()
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 visible match, got %d", len(matches))
+	}
+	if idx := f.stableIndex(matches[0].Index); idx != 0 {
+		t.Fatalf("stableIndex(visibleMatches()[0].Index) = %d, want 0", idx)
+	}
+}
+
+// TestToggleSelectionUnchecksWhileShowingSelected is the user-facing
+// symptom of the same bug: the first selected choice could never be
+// unchecked while "show selected" was active, because ToggleSelection
+// silently no-ops on a negative stable index.
+func TestToggleSelectionUnchecksWhileShowingSelected(t *testing.T) {
+	f := New(WithChoices([]string{"a", "b", "c"}), WithLimit(3))
+	f.selected[0] = struct{}{}
+	f.numSelected = 1
+	f.matchOffset = 1
+	f.showSelected = true
+	f.cursor = 0
+
+	f.ToggleSelection()
+
+	if _, ok := f.selected[0]; ok {
+		t.Fatal("ToggleSelection should have unchecked the first selected choice")
+	}
+	if f.numSelected != 0 {
+		t.Fatalf("numSelected = %d, want 0", f.numSelected)
+	}
+}
@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+)
+
+// streamBatchSize is the number of newline-separated choices buffered before
+// being flushed to the UI as a choicesAppendedMsg, so that large inputs
+// (e.g. `find | gum filter --stream`) don't repaint once per line.
+const streamBatchSize = 64
+
+// choicesAppendedMsg carries a batch of newly read choices in --stream mode.
+type choicesAppendedMsg struct {
+	choices []string
+}
+
+// choicesDoneMsg signals that stdin has been fully consumed.
+type choicesDoneMsg struct{}
+
+// submitMsg lets Quit submit the current selection and stop Run, the same
+// way pressing the submit key does. A bare tea.Quit() won't do: Bubble Tea's
+// event loop special-cases tea.QuitMsg and returns without ever calling
+// Update, so submitted would never be set to true.
+type submitMsg struct{}
+
+// choicesSetMsg, headerSetMsg and limitSetMsg let SetChoices, SetHeader and
+// SetLimit reach a Filter whose Bubble Tea program is already running.
+type choicesSetMsg struct {
+	choices []string
+}
+
+type headerSetMsg struct {
+	header string
+}
+
+type limitSetMsg struct {
+	limit int
+}
+
+// matchesMsg carries the result of an asynchronous, cancelable matchCmd.
+type matchesMsg struct {
+	query             string
+	matches           []fuzzy.Match
+	yOffsetFromBottom int
+}
+
+// NewChoicesChannel starts a goroutine that scans newline-separated choices
+// from r and sends them to the returned channel in small batches, so the TUI
+// can start rendering before all of stdin has been read. The channel is
+// closed once r is exhausted.
+func NewChoicesChannel(r io.Reader) chan []string {
+	ch := make(chan []string)
+	go func() {
+		defer close(ch)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		batch := make([]string, 0, streamBatchSize)
+		for scanner.Scan() {
+			batch = append(batch, scanner.Text())
+			if len(batch) >= streamBatchSize {
+				ch <- batch
+				batch = make([]string, 0, streamBatchSize)
+			}
+		}
+		if len(batch) > 0 {
+			ch <- batch
+		}
+	}()
+	return ch
+}
+
+// waitForChoicesCmd waits for the next batch of streamed choices without
+// blocking the UI goroutine.
+func waitForChoicesCmd(ch chan []string) tea.Cmd {
+	return func() tea.Msg {
+		choices, ok := <-ch
+		if !ok {
+			return choicesDoneMsg{}
+		}
+		return choicesAppendedMsg{choices: choices}
+	}
+}
+
+// matchCmd runs the configured matcher off the UI goroutine, honoring ctx
+// cancellation so a fast typist never waits on a stale keystroke's matching
+// pass to finish before seeing the next one's results. A custom matcher
+// (set via WithMatcher) always takes precedence over the built-in
+// algorithms.
+func matchCmd(ctx context.Context, query string, choices []string, matcher Matcher, fuzzyEnabled bool, algorithm string, sortMatches bool, yOffsetFromBottom int) tea.Cmd {
+	return func() tea.Msg {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var matches []fuzzy.Match
+		switch {
+		case matcher != nil:
+			matches = matcher.Match(query, choices)
+		case !fuzzyEnabled:
+			matches = exactMatches(query, choices)
+		case algorithm == AlgorithmSubstring:
+			matches = exactMatches(query, choices)
+		case algorithm == AlgorithmSmithWaterman:
+			matches = smithWatermanMatches(query, choices)
+		case algorithm == AlgorithmSubsequence:
+			matches = subsequenceMatches(query, choices)
+		case sortMatches:
+			matches = fuzzy.Find(query, choices)
+		default:
+			matches = fuzzy.FindNoSort(query, choices)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		return matchesMsg{query: query, matches: matches, yOffsetFromBottom: yOffsetFromBottom}
+	}
+}
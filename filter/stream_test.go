@@ -0,0 +1,41 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestWithChoicesChannelStreamsIntoFilter exercises the streaming path end
+// to end: NewChoicesChannel feeds lines from a reader into the channel
+// WithChoicesChannel hands to the Filter, waitForChoicesCmd picks up the
+// batch, and Update folds it into m.choices. Before WithChoicesChannel
+// existed there was no way to attach such a channel to a Filter at all, so
+// --stream mode had nothing to wire the channel to.
+func TestWithChoicesChannelStreamsIntoFilter(t *testing.T) {
+	ch := NewChoicesChannel(strings.NewReader("foo\nbar\nbaz\n"))
+
+	f := New(WithChoicesChannel(ch))
+	if !f.stream {
+		t.Fatal("WithChoicesChannel should enable stream mode")
+	}
+	if f.choicesCh == nil {
+		t.Fatal("WithChoicesChannel should attach the channel to the Filter")
+	}
+
+	msg := waitForChoicesCmd(f.choicesCh)()
+	appended, ok := msg.(choicesAppendedMsg)
+	if !ok {
+		t.Fatalf("expected choicesAppendedMsg, got %T", msg)
+	}
+
+	model, _ := Filter(*f).Update(appended)
+	updated := model.(Filter)
+
+	if len(updated.choices) != 3 {
+		t.Fatalf("expected 3 streamed choices, got %d (%v)", len(updated.choices), updated.choices)
+	}
+
+	if msg := waitForChoicesCmd(updated.choicesCh)(); msg != (choicesDoneMsg{}) {
+		t.Fatalf("expected choicesDoneMsg once the reader is exhausted, got %T", msg)
+	}
+}